@@ -0,0 +1,152 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// scriptFilePattern matches migration script filenames of the form
+// "NNN_description.up.{json,bson}" or "NNN_description.down.{json,bson}".
+var scriptFilePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.(json|bson)$`)
+
+type scriptPair struct {
+	description string
+	up, down    string
+}
+
+// LoadFromFS discovers migration scripts in dir within fsys and returns them as Migration
+// values, stably sorted by their numeric prefix. Files are named "NNN_description.up.{ext}"
+// and "NNN_description.down.{ext}"; .json and .bson files are decoded as a MongoDB command
+// document (e.g. an aggregation pipeline) and run via db.RunCommand. This lets migrations be
+// authored by teammates who don't want to write or compile Go. Two files sharing a version with
+// different descriptions is an error.
+//
+// A server-side "eval" script (.js) is deliberately not supported: the eval command was removed
+// in MongoDB 4.2 and would fail on every currently supported server.
+func LoadFromFS(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: read migrations dir %q: %w", dir, err)
+	}
+
+	byVersion := make(map[uint64]*scriptPair)
+	versions := make([]uint64, 0, len(entries))
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := scriptFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: parse migration version from %q: %w", entry.Name(), err)
+		}
+		description, direction := match[2], match[3]
+
+		pair, ok := byVersion[version]
+		if !ok {
+			pair = &scriptPair{description: description}
+			byVersion[version] = pair
+			versions = append(versions, version)
+		} else if pair.description != description {
+			return nil, fmt.Errorf("migrate: duplicate migration version %d (%q and %q)", version, pair.description, description)
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		switch direction {
+		case "up":
+			pair.up = path
+		case "down":
+			pair.down = path
+		}
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool { return versions[i] < versions[j] })
+
+	migrations := make([]Migration, 0, len(versions))
+	for _, version := range versions {
+		pair := byVersion[version]
+		migration := Migration{
+			Version:     version,
+			Description: pair.description,
+		}
+		if pair.up != "" {
+			migration.Up = scriptMigrateFunc(fsys, pair.up)
+		}
+		if pair.down != "" {
+			migration.Down = scriptMigrateFunc(fsys, pair.down)
+		}
+		migrations = append(migrations, migration)
+	}
+
+	return migrations, nil
+}
+
+// LoadFromDir is like LoadFromFS but reads migration scripts from a directory on the local
+// filesystem.
+func LoadFromDir(path string) ([]Migration, error) {
+	return LoadFromFS(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+}
+
+// Register loads migration scripts from dir within fsys via LoadFromFS and constructs a
+// Migrate for the given database from them.
+func Register(db *mongo.Database, fsys fs.FS, dir string) (*Migrate, error) {
+	migrations, err := LoadFromFS(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	return NewMigrate(db, migrations...), nil
+}
+
+func scriptMigrateFunc(fsys fs.FS, path string) MigrateFunc {
+	return func(ctx context.Context, db *mongo.Database) error {
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return fmt.Errorf("migrate: read migration script %q: %w", path, err)
+		}
+
+		command, err := decodeScriptCommand(path, content)
+		if err != nil {
+			return err
+		}
+
+		if err := db.RunCommand(ctx, command).Err(); err != nil {
+			return fmt.Errorf("migrate: run migration script %q: %w", path, err)
+		}
+		return nil
+	}
+}
+
+func decodeScriptCommand(path string, content []byte) (bson.D, error) {
+	switch {
+	case strings.HasSuffix(path, ".json"):
+		var command bson.D
+		if err := bson.UnmarshalExtJSON(content, false, &command); err != nil {
+			return nil, fmt.Errorf("migrate: decode migration script %q: %w", path, err)
+		}
+		return command, nil
+	case strings.HasSuffix(path, ".bson"):
+		var command bson.D
+		if err := bson.Unmarshal(content, &command); err != nil {
+			return nil, fmt.Errorf("migrate: decode migration script %q: %w", path, err)
+		}
+		return command, nil
+	default:
+		return nil, fmt.Errorf("migrate: unsupported migration script extension for %q", path)
+	}
+}
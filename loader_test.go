@@ -0,0 +1,95 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestLoadFromFSOrdersByNumericPrefixAndPairsUpDown(t *testing.T) {
+	fsys := fstest.MapFS{
+		"002_add_index.up.json":   &fstest.MapFile{Data: []byte(`{"createIndexes":"widgets","indexes":[]}`)},
+		"002_add_index.down.json": &fstest.MapFile{Data: []byte(`{"dropIndexes":"widgets","index":"*"}`)},
+		"001_add_users.up.bson":   &fstest.MapFile{Data: mustBSON(t, bson.D{{Key: "create", Value: "users"}})},
+		"README.md":               &fstest.MapFile{Data: []byte("not a migration")},
+	}
+
+	migrations, err := LoadFromFS(fsys, ".")
+	if err != nil {
+		t.Fatalf("LoadFromFS: %v", err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("expected 2 migrations, got %d", len(migrations))
+	}
+	if migrations[0].Version != 1 || migrations[1].Version != 2 {
+		t.Fatalf("expected versions [1, 2] in order, got [%d, %d]", migrations[0].Version, migrations[1].Version)
+	}
+	if migrations[0].Description != "add_users" {
+		t.Fatalf("expected description %q, got %q", "add_users", migrations[0].Description)
+	}
+	if migrations[0].Up == nil || migrations[0].Down != nil {
+		t.Fatal("version 1 should only have an Up script")
+	}
+	if migrations[1].Up == nil || migrations[1].Down == nil {
+		t.Fatal("version 2 should have both Up and Down scripts")
+	}
+}
+
+func TestLoadFromFSRejectsDuplicateVersionWithDifferentDescription(t *testing.T) {
+	fsys := fstest.MapFS{
+		"001_add_users.up.json": &fstest.MapFile{Data: []byte(`{"create":"users"}`)},
+		"001_add_orgs.up.json":  &fstest.MapFile{Data: []byte(`{"create":"orgs"}`)},
+	}
+
+	_, err := LoadFromFS(fsys, ".")
+	if err == nil {
+		t.Fatal("expected an error for duplicate migration version with different descriptions")
+	}
+}
+
+func TestLoadFromFSIgnoresJSScripts(t *testing.T) {
+	fsys := fstest.MapFS{
+		"001_legacy.up.js": &fstest.MapFile{Data: []byte("db.widgets.insert({})")},
+	}
+
+	migrations, err := LoadFromFS(fsys, ".")
+	if err != nil {
+		t.Fatalf("LoadFromFS: %v", err)
+	}
+	if len(migrations) != 0 {
+		t.Fatalf("expected .js scripts to be ignored (eval was removed in MongoDB 4.2), got %d migrations", len(migrations))
+	}
+}
+
+func TestDecodeScriptCommandJSONAndBSON(t *testing.T) {
+	jsonCmd, err := decodeScriptCommand("001_x.up.json", []byte(`{"create":"widgets"}`))
+	if err != nil {
+		t.Fatalf("decode json: %v", err)
+	}
+	if len(jsonCmd) != 1 || jsonCmd[0].Key != "create" {
+		t.Fatalf("unexpected decoded json command: %v", jsonCmd)
+	}
+
+	raw := mustBSON(t, bson.D{{Key: "create", Value: "widgets"}})
+	bsonCmd, err := decodeScriptCommand("001_x.up.bson", raw)
+	if err != nil {
+		t.Fatalf("decode bson: %v", err)
+	}
+	if len(bsonCmd) != 1 || bsonCmd[0].Key != "create" {
+		t.Fatalf("unexpected decoded bson command: %v", bsonCmd)
+	}
+
+	if _, err := decodeScriptCommand("001_x.up.js", []byte("ignored")); err == nil {
+		t.Fatal("expected an error decoding an unsupported .js script")
+	}
+}
+
+func mustBSON(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := bson.Marshal(v)
+	if err != nil {
+		t.Fatalf("bson.Marshal: %v", err)
+	}
+	return data
+}
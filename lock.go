@@ -0,0 +1,198 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const lockDocumentID = "migrate-lock"
+
+const (
+	defaultLockTimeout       = time.Minute
+	defaultLockRetryInterval = 500 * time.Millisecond
+	defaultLockRetryAttempts = 10
+)
+
+// ErrLocked is returned when the migration lock is held by another process and could not be
+// acquired within the configured retry budget.
+var ErrLocked = errors.New("migrate: migrations are locked by another process")
+
+type lockRecord struct {
+	ID         string    `bson:"_id"`
+	Owner      string    `bson:"owner"`
+	AcquiredAt time.Time `bson:"acquiredAt"`
+	ExpiresAt  time.Time `bson:"expiresAt"`
+}
+
+// SetLockCollection enables a distributed advisory lock, backed by the named collection, so that
+// two processes (e.g. rolling-deployed pods) cannot run Up/Down/SetVersion at the same time.
+// Locking is disabled by default; calling this enables it.
+func (m *Migrate) SetLockCollection(name string) {
+	m.lockCollection = name
+}
+
+// SetLockTimeout sets how long an acquired lock is honored before it is considered stale and can
+// be stolen by another process, bounding how long a crashed process can wedge a deployment.
+// By default, it is one minute.
+//
+// The lock is renewed automatically in the background at half this interval for as long as a
+// migration run is in progress, so a healthy run is never at risk of losing its own lock. Set
+// this comfortably above the time a single migration step is expected to take anyway: renewal
+// only protects a run that is still alive, and a process that stops renewing (it crashed, or was
+// killed, or lost its database connection) still has its lock correctly reclaimed once the
+// timeout elapses, which is the whole point of the timeout.
+func (m *Migrate) SetLockTimeout(d time.Duration) {
+	m.lockTimeout = d
+}
+
+// SetLockRetry sets how often, and how many times, to retry acquiring a held lock before giving
+// up with ErrLocked. By default, it retries every 500ms for 10 attempts.
+func (m *Migrate) SetLockRetry(interval time.Duration, attempts int) {
+	m.lockRetryInterval = interval
+	m.lockRetryAttempts = attempts
+}
+
+func (m *Migrate) lockEnabled() bool {
+	return m.lockCollection != ""
+}
+
+// acquireLock acquires the advisory lock, if locking is enabled, stealing it from a previous
+// holder once its timeout has elapsed. While the lock is held, it is renewed in the background
+// at half the lock timeout so a run that takes longer than SetLockTimeout doesn't have its lock
+// stolen out from under it partway through; see SetLockTimeout. It returns a release function
+// that must always be called, which stops the renewal and is a no-op when locking is disabled.
+func (m *Migrate) acquireLock(ctx context.Context) (func(context.Context), error) {
+	if !m.lockEnabled() {
+		return func(context.Context) {}, nil
+	}
+
+	timeout := m.lockTimeout
+	if timeout <= 0 {
+		timeout = defaultLockTimeout
+	}
+	interval := m.lockRetryInterval
+	if interval <= 0 {
+		interval = defaultLockRetryInterval
+	}
+	attempts := m.lockRetryAttempts
+	if attempts <= 0 {
+		attempts = defaultLockRetryAttempts
+	}
+
+	collection := m.db.Collection(m.lockCollection)
+	owner := lockOwner()
+
+	for attempt := 0; ; attempt++ {
+		now := time.Now().UTC()
+		rec := lockRecord{
+			ID:         lockDocumentID,
+			Owner:      owner,
+			AcquiredAt: now,
+			ExpiresAt:  now.Add(timeout),
+		}
+
+		_, err := collection.InsertOne(ctx, rec)
+		if err == nil {
+			// Match on owner and acquiredAt, not just _id: if this lock is stolen for being
+			// stale while we still hold it, the thief's document also has _id lockDocumentID,
+			// and releasing/renewing by _id alone would touch their live lock out from under them.
+			held := lockHold{owner: owner, acquiredAt: rec.AcquiredAt}
+			stopRenewal := m.startLockRenewal(collection, held, timeout)
+			return func(ctx context.Context) {
+				stopRenewal()
+				_, _ = collection.DeleteOne(ctx, lockDocFilter(held))
+			}, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return nil, fmt.Errorf("migrate: acquire lock: %w", err)
+		}
+
+		// the lock is held by someone else; steal it if it has expired and retry immediately.
+		result, delErr := collection.DeleteOne(ctx, bson.D{
+			bson.E{Key: "_id", Value: lockDocumentID},
+			bson.E{Key: "expiresAt", Value: bson.D{bson.E{Key: "$lte", Value: now}}},
+		})
+		if delErr == nil && result.DeletedCount > 0 {
+			continue
+		}
+
+		if attempt >= attempts {
+			return nil, ErrLocked
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// lockHold identifies a specific, currently-held lock document, as opposed to whatever document
+// (possibly belonging to a different holder) currently sits at _id lockDocumentID.
+type lockHold struct {
+	owner      string
+	acquiredAt time.Time
+}
+
+// lockDocFilter builds the filter that matches held's lock document and no other, so that a
+// delete or renewal update can never affect a lock acquired by someone else after held expired.
+func lockDocFilter(held lockHold) bson.D {
+	return bson.D{
+		bson.E{Key: "_id", Value: lockDocumentID},
+		bson.E{Key: "owner", Value: held.owner},
+		bson.E{Key: "acquiredAt", Value: held.acquiredAt},
+	}
+}
+
+// startLockRenewal periodically pushes out held's expiresAt, at half the lock timeout, for as
+// long as the migration run is in progress. It returns a stop function that must be called once
+// the lock is released, which blocks until the renewal goroutine has exited. A renewal that fails
+// (e.g. the lock was already stolen, or a transient network error) is silently skipped and
+// retried on the next tick; acquireLock's own stale-lock stealing is what bounds the damage if
+// renewal can never succeed.
+func (m *Migrate) startLockRenewal(collection *mongo.Collection, held lockHold, timeout time.Duration) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(timeout / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), timeout/2)
+				_, _ = collection.UpdateOne(ctx, lockDocFilter(held), bson.D{
+					bson.E{Key: "$set", Value: bson.D{
+						bson.E{Key: "expiresAt", Value: time.Now().UTC().Add(timeout)},
+					}},
+				})
+				cancel()
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func lockOwner() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
@@ -0,0 +1,64 @@
+package migrate
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestLockDocFilterMatchesOwnerAndAcquiredAt checks the filter used by release and renewal, which
+// guards the scenario the lock timeout exists for: process A's lock expires and is stolen by
+// process B, then A's deferred release or a delayed renewal from A fires. The filter must
+// constrain on owner+acquiredAt, not just _id, so it can never touch B's live lock.
+func TestLockDocFilterMatchesOwnerAndAcquiredAt(t *testing.T) {
+	held := lockHold{owner: "host-1-123", acquiredAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	filter := lockDocFilter(held)
+
+	keys := make(map[string]any)
+	for _, elem := range filter {
+		keys[elem.Key] = elem.Value
+	}
+	if keys["_id"] != lockDocumentID {
+		t.Fatalf("filter %v must match _id %q", filter, lockDocumentID)
+	}
+	if keys["owner"] != held.owner || keys["acquiredAt"] != held.acquiredAt {
+		t.Fatalf("filter %v must constrain on this hold's owner and acquiredAt, not just _id, so a stolen lock is never touched out from under its new holder", filter)
+	}
+}
+
+func TestLockOwnerIsStableAndNonEmpty(t *testing.T) {
+	owner := lockOwner()
+	if owner == "" {
+		t.Fatal("lockOwner() returned empty string")
+	}
+	if owner != lockOwner() {
+		t.Fatalf("lockOwner() is not stable within a process: %q != %q", owner, lockOwner())
+	}
+}
+
+func TestAcquireLockDisabledIsNoop(t *testing.T) {
+	m := &Migrate{}
+	release, err := m.acquireLock(context.Background())
+	if err != nil {
+		t.Fatalf("acquireLock with no lock collection set should be a no-op, got err: %v", err)
+	}
+	release(context.Background())
+}
+
+func TestLockRecordExpiry(t *testing.T) {
+	now := time.Now().UTC()
+	rec := lockRecord{ID: lockDocumentID, Owner: "host-1", AcquiredAt: now, ExpiresAt: now.Add(time.Minute)}
+	if !rec.ExpiresAt.After(now) {
+		t.Fatal("freshly acquired lock should not be expired")
+	}
+}
+
+func TestStartLockRenewalStopsCleanly(t *testing.T) {
+	// startLockRenewal is only exercised against a real *mongo.Collection in production; here we
+	// just confirm its stop function returns promptly and doesn't leak the goroutine, using a
+	// renewal interval far longer than the test so no tick (and thus no database call) happens.
+	held := lockHold{owner: lockOwner(), acquiredAt: time.Now().UTC()}
+	m := &Migrate{}
+	stop := m.startLockRenewal(nil, held, time.Hour)
+	stop()
+}
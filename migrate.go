@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -12,6 +13,11 @@ import (
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
+// illegalOperationCode is the server error code ("IllegalOperation") MongoDB returns when a
+// transaction is attempted against a deployment that doesn't support them, e.g. a standalone
+// server: "Transaction numbers are only allowed on a replica set member or mongos".
+const illegalOperationCode = 20
+
 type collectionSpecification struct {
 	Name string `bson:"name"`
 	Type string `bson:"type"`
@@ -23,6 +29,14 @@ type versionRecord struct {
 	Timestamp   time.Time `bson:"timestamp"`
 }
 
+// MigrationStatus describes the applied state of a single registered migration.
+type MigrationStatus struct {
+	Version     uint64
+	Description string
+	Applied     bool
+	AppliedAt   time.Time
+}
+
 const defaultMigrationsCollection = "migrations"
 
 // AllAvailable used in "Up" or "Down" methods to run all available migrations.
@@ -38,6 +52,13 @@ type Migrate struct {
 	migrations           []Migration
 	migrationsCollection string
 	log                  Logger
+	useTransactions      bool
+	lockCollection       string
+	lockTimeout          time.Duration
+	lockRetryInterval    time.Duration
+	lockRetryAttempts    int
+	dryRun               bool
+	hook                 EventHook
 }
 
 func NewMigrate(db *mongo.Database, migrations ...Migration) *Migrate {
@@ -56,6 +77,51 @@ func (m *Migrate) SetMigrationsCollection(name string) {
 	m.migrationsCollection = name
 }
 
+// SetUseTransactions enables wrapping each migration's Up/Down call together with its
+// version record update in a single MongoDB session transaction, so a failing migration
+// never leaves the version collection out of sync with the actual schema state.
+// This requires the server to be part of a replica set or sharded cluster; against a
+// standalone server, Up/Down will fail with a clear error as soon as a transaction is attempted.
+func (m *Migrate) SetUseTransactions(use bool) {
+	m.useTransactions = use
+}
+
+// runStep executes fn, optionally wrapping it in a MongoDB session transaction when
+// transactions are enabled. fn must perform all its database operations using the ctx
+// it is given, so that they participate in the transaction.
+func (m *Migrate) runStep(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !m.useTransactions {
+		return fn(ctx)
+	}
+
+	session, err := m.db.Client().StartSession()
+	if err != nil {
+		return fmt.Errorf("migrate: start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (any, error) {
+		return nil, fn(ctx)
+	})
+	if err != nil {
+		if isTransactionsUnsupportedErr(err) {
+			return fmt.Errorf("migrate: run transactional migration step (requires a replica set or mongos): %w", err)
+		}
+		return fmt.Errorf("migrate: run transactional migration step: %w", err)
+	}
+	return nil
+}
+
+// isTransactionsUnsupportedErr reports whether err indicates the server deployment itself
+// doesn't support transactions, as opposed to an ordinary failure inside the migration.
+func isTransactionsUnsupportedErr(err error) bool {
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Code == illegalOperationCode {
+		return true
+	}
+	return strings.Contains(err.Error(), "Transaction numbers are only allowed on a replica set member or mongos")
+}
+
 func (m *Migrate) isCollectionExist(ctx context.Context, name string) (isExist bool, err error) {
 	collections, err := m.getCollections(ctx)
 	if err != nil {
@@ -156,6 +222,18 @@ func (m *Migrate) Version(ctx context.Context) (uint64, string, error) {
 
 // SetVersion forcibly changes database version to provided one.
 func (m *Migrate) SetVersion(ctx context.Context, version uint64, description string) error {
+	release, err := m.acquireLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer release(ctx)
+
+	return m.setVersion(ctx, version, description)
+}
+
+// setVersion is the unlocked implementation of SetVersion, used internally by Up/Down/UpTo/DownTo
+// which already hold the migration lock for the duration of their run.
+func (m *Migrate) setVersion(ctx context.Context, version uint64, description string) error {
 	rec := versionRecord{
 		Version:     version,
 		Timestamp:   time.Now().UTC(),
@@ -174,29 +252,28 @@ func (m *Migrate) SetVersion(ctx context.Context, version uint64, description st
 // If n<=0 all "up" migrations with newer versions will be performed.
 // If n>0 only n migrations with newer version will be performed.
 func (m *Migrate) Up(ctx context.Context, n int) error {
-	currentVersion, _, err := m.Version(ctx)
+	release, err := m.acquireLock(ctx)
 	if err != nil {
 		return err
 	}
-	if n <= 0 || n > len(m.migrations) {
-		n = len(m.migrations)
+	defer release(ctx)
+
+	currentVersion, _, err := m.Version(ctx)
+	if err != nil {
+		return err
 	}
 	migrationSort(m.migrations)
 
-	for i, p := 0, 0; i < len(m.migrations) && p < n; i++ {
-		migration := m.migrations[i]
-		if migration.Version <= currentVersion || migration.Up == nil {
-			continue
-		}
-		p++
-		if err := migration.Up(ctx, m.db); err != nil {
-			return err
-		}
-		if err := m.SetVersion(ctx, migration.Version, migration.Description); err != nil {
+	for _, step := range selectUpSteps(m.migrations, currentVersion, n) {
+		migration, _ := migrationByVersion(m.migrations, step.Version)
+		if err := m.applyStep(ctx, step, func(ctx context.Context) error {
+			if err := migration.Up(ctx, m.db); err != nil {
+				return err
+			}
+			return m.setVersion(ctx, migration.Version, migration.Description)
+		}); err != nil {
 			return err
 		}
-
-		m.printUp(migration.Version, migration.Description)
 	}
 	return nil
 }
@@ -205,38 +282,268 @@ func (m *Migrate) Up(ctx context.Context, n int) error {
 // If n<=0 all "down" migrations with older version will be performed.
 // If n>0 only n migrations with older version will be performed.
 func (m *Migrate) Down(ctx context.Context, n int) error {
+	release, err := m.acquireLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer release(ctx)
+
+	return m.down(ctx, n)
+}
+
+// down is the unlocked implementation of Down, used internally by Redo which holds the
+// migration lock for the duration of its whole down-then-up sequence.
+func (m *Migrate) down(ctx context.Context, n int) error {
 	currentVersion, _, err := m.Version(ctx)
 	if err != nil {
 		return err
 	}
-	if n <= 0 || n > len(m.migrations) {
-		n = len(m.migrations)
+	migrationSort(m.migrations)
+
+	for _, step := range selectDownSteps(m.migrations, currentVersion, n) {
+		migration, _ := migrationByVersion(m.migrations, step.Version)
+		prevMigration := previousMigration(m.migrations, step.Version)
+		if err := m.applyStep(ctx, step, func(ctx context.Context) error {
+			if err := migration.Down(ctx, m.db); err != nil {
+				return err
+			}
+			return m.setVersion(ctx, prevMigration.Version, prevMigration.Description)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpTo performs "up" migrations for every unapplied migration with Version <= targetVersion, in ascending order.
+// It is useful for pinning a database to a specific schema version, e.g. in CI or when cherry-picking a hotfix migration.
+func (m *Migrate) UpTo(ctx context.Context, targetVersion uint64) error {
+	release, err := m.acquireLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer release(ctx)
+
+	return m.upTo(ctx, targetVersion)
+}
+
+// upTo is the unlocked implementation of UpTo, used internally by Redo which holds the
+// migration lock for the duration of its whole down-then-up sequence.
+func (m *Migrate) upTo(ctx context.Context, targetVersion uint64) error {
+	currentVersion, _, err := m.Version(ctx)
+	if err != nil {
+		return err
 	}
 	migrationSort(m.migrations)
 
-	for i, p := len(m.migrations)-1, 0; i >= 0 && p < n; i-- {
-		migration := m.migrations[i]
-		if migration.Version > currentVersion || migration.Down == nil {
+	for _, step := range selectUpToSteps(m.migrations, currentVersion, targetVersion) {
+		migration, _ := migrationByVersion(m.migrations, step.Version)
+		if err := m.applyStep(ctx, step, func(ctx context.Context) error {
+			if err := migration.Up(ctx, m.db); err != nil {
+				return err
+			}
+			return m.setVersion(ctx, migration.Version, migration.Description)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectUpToSteps returns the ordered steps UpTo(targetVersion) would execute: every unapplied
+// migration with Version <= targetVersion, ascending. migrations must already be sorted ascending
+// by Version (as migrationSort leaves them).
+func selectUpToSteps(migrations []Migration, currentVersion, targetVersion uint64) []PlannedStep {
+	var steps []PlannedStep
+	for _, migration := range migrations {
+		if migration.Version <= currentVersion || migration.Version > targetVersion || migration.Up == nil {
 			continue
 		}
-		p++
-		if err := migration.Down(ctx, m.db); err != nil {
+		steps = append(steps, PlannedStep{Version: migration.Version, Description: migration.Description, Direction: DirectionUp})
+	}
+	return steps
+}
+
+// migrationByVersion finds the migration with the given version, reporting false if none is
+// registered.
+func migrationByVersion(migrations []Migration, version uint64) (Migration, bool) {
+	for _, migration := range migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// DownTo performs "down" migrations for every applied migration with Version > targetVersion, in descending order.
+func (m *Migrate) DownTo(ctx context.Context, targetVersion uint64) error {
+	release, err := m.acquireLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer release(ctx)
+
+	currentVersion, _, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	migrationSort(m.migrations)
+
+	for _, step := range selectDownToSteps(m.migrations, currentVersion, targetVersion) {
+		migration, _ := migrationByVersion(m.migrations, step.Version)
+		prevMigration := previousMigration(m.migrations, step.Version)
+		if err := m.applyStep(ctx, step, func(ctx context.Context) error {
+			if err := migration.Down(ctx, m.db); err != nil {
+				return err
+			}
+			return m.setVersion(ctx, prevMigration.Version, prevMigration.Description)
+		}); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		var prevMigration Migration
-		if i == 0 {
-			prevMigration = Migration{Version: 0}
-		} else {
-			prevMigration = m.migrations[i-1]
+// selectDownToSteps returns the ordered steps DownTo(targetVersion) would execute: every applied
+// migration with Version > targetVersion, descending. migrations must already be sorted ascending
+// by Version (as migrationSort leaves them).
+func selectDownToSteps(migrations []Migration, currentVersion, targetVersion uint64) []PlannedStep {
+	var steps []PlannedStep
+	for i := len(migrations) - 1; i >= 0; i-- {
+		migration := migrations[i]
+		if migration.Version > currentVersion || migration.Version <= targetVersion || migration.Down == nil {
+			continue
 		}
-		if err := m.SetVersion(ctx, prevMigration.Version, prevMigration.Description); err != nil {
-			return err
+		steps = append(steps, PlannedStep{Version: migration.Version, Description: migration.Description, Direction: DirectionDown})
+	}
+	return steps
+}
+
+// previousMigration returns the migration immediately preceding version in migrations (which must
+// already be sorted ascending by Version), or the zero Migration (Version 0) if version is the
+// first migration or isn't registered. This is the version a Down step for version rolls back to.
+func previousMigration(migrations []Migration, version uint64) Migration {
+	for i, migration := range migrations {
+		if migration.Version == version {
+			if i == 0 {
+				return Migration{Version: 0}
+			}
+			return migrations[i-1]
 		}
+	}
+	return Migration{Version: 0}
+}
 
-		m.printDown(migration.Version, migration.Description)
+// redoTargetVersion validates that there is a migration to redo and returns the version Redo
+// should reapply after rolling back one step.
+func redoTargetVersion(currentVersion uint64) (uint64, error) {
+	if currentVersion == 0 {
+		return 0, errors.New("migrate: no migration to redo")
 	}
-	return nil
+	return currentVersion, nil
+}
+
+// Redo rolls back the most recently applied migration and then re-applies it.
+func (m *Migrate) Redo(ctx context.Context) error {
+	release, err := m.acquireLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer release(ctx)
+
+	currentVersion, _, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	target, err := redoTargetVersion(currentVersion)
+	if err != nil {
+		return err
+	}
+
+	if err := m.down(ctx, 1); err != nil {
+		return err
+	}
+	return m.upTo(ctx, target)
+}
+
+// Status returns one entry per registered migration, reporting whether it has been applied and,
+// if so, when. This is useful after SetVersion has been used to force a version, or when
+// migrations have been added or removed since the last run.
+func (m *Migrate) Status(ctx context.Context) ([]MigrationStatus, error) {
+	currentVersion, _, err := m.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := m.db.Collection(m.migrationsCollection).Find(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []versionRecord
+	for cursor.Next(ctx) {
+		var rec versionRecord
+		if err := cursor.Decode(&rec); err != nil {
+			return nil, err
+		}
+		rows = append(rows, rec)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return buildStatuses(m.migrations, currentVersion, rows), nil
+}
+
+// buildStatuses joins registered migrations against the append-only version log. The log is a
+// transition log, not a set of currently-applied versions: a row exists for a migration's version
+// as soon as it is ever applied and is never deleted when it's later rolled back, so Applied must
+// be derived from version ordering (the same "Version <= currentVersion" rule Up/Down use to pick
+// their candidates), not from row presence. AppliedAt, where available, comes from the most
+// recent row recorded for that version.
+func buildStatuses(migrations []Migration, currentVersion uint64, rows []versionRecord) []MigrationStatus {
+	lastSeen := make(map[uint64]time.Time, len(rows))
+	for _, rec := range rows {
+		if seen, ok := lastSeen[rec.Version]; !ok || rec.Timestamp.After(seen) {
+			lastSeen[rec.Version] = rec.Timestamp
+		}
+	}
+
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	migrationSort(sorted)
+
+	statuses := make([]MigrationStatus, 0, len(sorted))
+	for _, migration := range sorted {
+		status := MigrationStatus{
+			Version:     migration.Version,
+			Description: migration.Description,
+			Applied:     migration.Version <= currentVersion,
+		}
+		if status.Applied {
+			status.AppliedAt = lastSeen[migration.Version]
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// Pending returns the subset of Status entries that have not yet been applied.
+func (m *Migrate) Pending(ctx context.Context) ([]MigrationStatus, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]MigrationStatus, 0, len(statuses))
+	for _, status := range statuses {
+		if !status.Applied {
+			pending = append(pending, status)
+		}
+	}
+	return pending, nil
 }
 
 // SetLogger sets a logger to print the migration process
@@ -0,0 +1,111 @@
+package migrate
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func TestSelectUpToStepsSkipsAppliedAndOutOfRangeMigrations(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Description: "one", Up: stubUp},
+		{Version: 2, Description: "two", Up: stubUp},
+		{Version: 3, Description: "three", Up: stubUp},
+	}
+
+	steps := selectUpToSteps(migrations, 1, 2)
+	if len(steps) != 1 || steps[0].Version != 2 {
+		t.Fatalf("expected only version 2 (applied=false, <= target 2), got %+v", steps)
+	}
+}
+
+func TestSelectUpToStepsSkipsMigrationsWithoutUp(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Description: "one"}, // no Up func registered
+		{Version: 2, Description: "two", Up: stubUp},
+	}
+
+	steps := selectUpToSteps(migrations, 0, 2)
+	if len(steps) != 1 || steps[0].Version != 2 {
+		t.Fatalf("expected version 1 to be skipped (no Up func), got %+v", steps)
+	}
+}
+
+func TestSelectDownToStepsOrdersDescendingAndRespectsTarget(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Description: "one", Down: stubDown},
+		{Version: 2, Description: "two", Down: stubDown},
+		{Version: 3, Description: "three", Down: stubDown},
+	}
+
+	steps := selectDownToSteps(migrations, 3, 1)
+	if len(steps) != 2 || steps[0].Version != 3 || steps[1].Version != 2 {
+		t.Fatalf("expected [3, 2] descending, got %+v", steps)
+	}
+}
+
+func TestPreviousMigrationFindsPredecessorOrZero(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Description: "one"},
+		{Version: 2, Description: "two"},
+		{Version: 5, Description: "five"},
+	}
+
+	if prev := previousMigration(migrations, 2); prev.Version != 1 {
+		t.Fatalf("expected predecessor of 2 to be 1, got %+v", prev)
+	}
+	if prev := previousMigration(migrations, 5); prev.Version != 2 {
+		t.Fatalf("expected predecessor of 5 to be 2, got %+v", prev)
+	}
+	if prev := previousMigration(migrations, 1); prev.Version != 0 {
+		t.Fatalf("expected the first migration to roll back to version 0, got %+v", prev)
+	}
+	if prev := previousMigration(migrations, 99); prev.Version != 0 {
+		t.Fatalf("expected an unregistered version to roll back to 0, got %+v", prev)
+	}
+}
+
+func TestMigrationByVersion(t *testing.T) {
+	migrations := []Migration{{Version: 1, Description: "one"}, {Version: 2, Description: "two"}}
+
+	migration, ok := migrationByVersion(migrations, 2)
+	if !ok || migration.Description != "two" {
+		t.Fatalf("expected to find version 2, got %+v, %v", migration, ok)
+	}
+	if _, ok := migrationByVersion(migrations, 99); ok {
+		t.Fatal("expected no match for an unregistered version")
+	}
+}
+
+func TestRedoTargetVersionRejectsZero(t *testing.T) {
+	if _, err := redoTargetVersion(0); err == nil {
+		t.Fatal("expected an error when there is no applied migration to redo")
+	}
+	target, err := redoTargetVersion(3)
+	if err != nil || target != 3 {
+		t.Fatalf("expected (3, nil), got (%d, %v)", target, err)
+	}
+}
+
+func TestRedoComposesDownThenUpToTheSameVersion(t *testing.T) {
+	// Redo's body is "roll back 1, then reapply up to the version that was current". This proves
+	// that composition lands back on the original version for a simple two-migration history.
+	migrations := []Migration{
+		{Version: 1, Description: "one", Up: stubUp, Down: stubDown},
+		{Version: 2, Description: "two", Up: stubUp, Down: stubDown},
+	}
+
+	downSteps := selectDownSteps(migrations, 2, 1)
+	if len(downSteps) != 1 || downSteps[0].Version != 2 {
+		t.Fatalf("expected Redo's down step to roll back version 2, got %+v", downSteps)
+	}
+
+	upSteps := selectUpToSteps(migrations, 1, 2)
+	if len(upSteps) != 1 || upSteps[0].Version != 2 {
+		t.Fatalf("expected Redo's up step to reapply version 2, got %+v", upSteps)
+	}
+}
+
+func stubUp(ctx context.Context, db *mongo.Database) error   { return nil }
+func stubDown(ctx context.Context, db *mongo.Database) error { return nil }
@@ -0,0 +1,149 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+)
+
+// Direction identifies whether a PlannedStep applies or reverts a migration.
+type Direction string
+
+const (
+	// DirectionUp marks a step that applies a migration.
+	DirectionUp Direction = "up"
+	// DirectionDown marks a step that reverts a migration.
+	DirectionDown Direction = "down"
+)
+
+// PlannedStep describes a single migration that would execute as part of an Up/Down run.
+type PlannedStep struct {
+	Version     uint64
+	Description string
+	Direction   Direction
+}
+
+// EventHook lets callers observe migration execution, e.g. to emit metrics or traces per migration.
+// Implementations must not block for long, since they are called synchronously from Up/Down.
+type EventHook interface {
+	// OnBeforeStep is called immediately before a planned step is about to execute.
+	OnBeforeStep(step PlannedStep)
+	// OnAfterStep is called after a planned step has executed successfully.
+	OnAfterStep(step PlannedStep)
+	// OnError is called when a planned step fails to execute.
+	OnError(step PlannedStep, err error)
+}
+
+// SetEventHook sets a hook that is notified before and after each migration step, and on error.
+func (m *Migrate) SetEventHook(hook EventHook) {
+	m.hook = hook
+}
+
+// SetDryRun enables a mode where Up/Down/UpTo/DownTo walk their plan and invoke the Logger and
+// EventHook as usual, but skip the actual migration.Up/Down and SetVersion calls. This supports
+// previewing what a run would do, e.g. in a CI job.
+func (m *Migrate) SetDryRun(dryRun bool) {
+	m.dryRun = dryRun
+}
+
+// Plan returns the ordered list of migrations that Up (direction DirectionUp) or Down
+// (direction DirectionDown) would execute, without applying them.
+func (m *Migrate) Plan(ctx context.Context, direction Direction, n int) ([]PlannedStep, error) {
+	currentVersion, _, err := m.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	migrationSort(m.migrations)
+
+	switch direction {
+	case DirectionUp:
+		return selectUpSteps(m.migrations, currentVersion, n), nil
+	case DirectionDown:
+		return selectDownSteps(m.migrations, currentVersion, n), nil
+	default:
+		return nil, fmt.Errorf("migrate: unknown direction %q", direction)
+	}
+}
+
+// selectUpSteps returns the ordered steps Up(n) would execute: up to n unapplied migrations with
+// an Up func, ascending. migrations must already be sorted ascending by Version.
+func selectUpSteps(migrations []Migration, currentVersion uint64, n int) []PlannedStep {
+	if n <= 0 || n > len(migrations) {
+		n = len(migrations)
+	}
+	var steps []PlannedStep
+	for i, p := 0, 0; i < len(migrations) && p < n; i++ {
+		migration := migrations[i]
+		if migration.Version <= currentVersion || migration.Up == nil {
+			continue
+		}
+		p++
+		steps = append(steps, PlannedStep{Version: migration.Version, Description: migration.Description, Direction: DirectionUp})
+	}
+	return steps
+}
+
+// selectDownSteps returns the ordered steps Down(n) would execute: up to n applied migrations
+// with a Down func, descending. migrations must already be sorted ascending by Version.
+func selectDownSteps(migrations []Migration, currentVersion uint64, n int) []PlannedStep {
+	if n <= 0 || n > len(migrations) {
+		n = len(migrations)
+	}
+	var steps []PlannedStep
+	for i, p := len(migrations)-1, 0; i >= 0 && p < n; i-- {
+		migration := migrations[i]
+		if migration.Version > currentVersion || migration.Down == nil {
+			continue
+		}
+		p++
+		steps = append(steps, PlannedStep{Version: migration.Version, Description: migration.Description, Direction: DirectionDown})
+	}
+	return steps
+}
+
+// applyStep runs a single planned step: it notifies the EventHook, performs the migration and
+// version update (unless dry-run is enabled), logs the result, and notifies the EventHook of any
+// error. op is skipped entirely in dry-run mode.
+func (m *Migrate) applyStep(ctx context.Context, step PlannedStep, op func(ctx context.Context) error) error {
+	m.notifyBefore(step)
+
+	if m.dryRun {
+		m.logStep(step)
+		m.notifyAfter(step)
+		return nil
+	}
+
+	if err := m.runStep(ctx, op); err != nil {
+		m.notifyError(step, err)
+		return err
+	}
+
+	m.logStep(step)
+	m.notifyAfter(step)
+	return nil
+}
+
+func (m *Migrate) logStep(step PlannedStep) {
+	if step.Direction == DirectionDown {
+		m.printDown(step.Version, step.Description)
+		return
+	}
+	m.printUp(step.Version, step.Description)
+}
+
+func (m *Migrate) notifyBefore(step PlannedStep) {
+	if m.hook != nil {
+		m.hook.OnBeforeStep(step)
+	}
+}
+
+func (m *Migrate) notifyAfter(step PlannedStep) {
+	if m.hook != nil {
+		m.hook.OnAfterStep(step)
+	}
+}
+
+func (m *Migrate) notifyError(step PlannedStep, err error) {
+	if m.hook != nil {
+		m.hook.OnError(step, err)
+	}
+}
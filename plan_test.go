@@ -0,0 +1,96 @@
+package migrate
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSelectUpStepsCapsAtNAndSkipsApplied(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Description: "one", Up: stubUp},
+		{Version: 2, Description: "two", Up: stubUp},
+		{Version: 3, Description: "three", Up: stubUp},
+	}
+
+	steps := selectUpSteps(migrations, 1, 1)
+	if len(steps) != 1 || steps[0].Version != 2 {
+		t.Fatalf("expected only version 2 (n=1 cap, version 1 already applied), got %+v", steps)
+	}
+
+	all := selectUpSteps(migrations, 1, 0)
+	if len(all) != 2 || all[0].Version != 2 || all[1].Version != 3 {
+		t.Fatalf("expected [2, 3] ascending when n<=0 means all, got %+v", all)
+	}
+}
+
+func TestSelectDownStepsCapsAtNAndOrdersDescending(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Description: "one", Down: stubDown},
+		{Version: 2, Description: "two", Down: stubDown},
+		{Version: 3, Description: "three", Down: stubDown},
+	}
+
+	steps := selectDownSteps(migrations, 3, 1)
+	if len(steps) != 1 || steps[0].Version != 3 {
+		t.Fatalf("expected only version 3 (n=1 cap, descending), got %+v", steps)
+	}
+
+	all := selectDownSteps(migrations, 2, 0)
+	if len(all) != 2 || all[0].Version != 2 || all[1].Version != 1 {
+		t.Fatalf("expected [2, 1] descending when n<=0 means all, got %+v", all)
+	}
+}
+
+func TestApplyStepSkipsOpInDryRun(t *testing.T) {
+	m := &Migrate{dryRun: true}
+	hook := &recordingHook{}
+	m.SetEventHook(hook)
+
+	step := PlannedStep{Version: 1, Description: "one", Direction: DirectionUp}
+	called := false
+	err := m.applyStep(context.Background(), step, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("applyStep: %v", err)
+	}
+	if called {
+		t.Fatal("dry-run must not invoke op")
+	}
+	if !hook.before || !hook.after || hook.errored {
+		t.Fatalf("dry-run should still notify OnBeforeStep/OnAfterStep, got %+v", hook)
+	}
+}
+
+func TestApplyStepRunsOpAndNotifiesErrorWhenNotDryRun(t *testing.T) {
+	m := &Migrate{}
+	hook := &recordingHook{}
+	m.SetEventHook(hook)
+
+	step := PlannedStep{Version: 1, Description: "one", Direction: DirectionUp}
+	wantErr := errAlwaysFails
+	err := m.applyStep(context.Background(), step, func(ctx context.Context) error {
+		return wantErr
+	})
+	if err != wantErr {
+		t.Fatalf("expected op's error to propagate, got %v", err)
+	}
+	if !hook.before || hook.after || !hook.errored {
+		t.Fatalf("a failing op should notify OnBeforeStep and OnError but not OnAfterStep, got %+v", hook)
+	}
+}
+
+var errAlwaysFails = &stubError{"op always fails"}
+
+type stubError struct{ msg string }
+
+func (e *stubError) Error() string { return e.msg }
+
+type recordingHook struct {
+	before, after, errored bool
+}
+
+func (h *recordingHook) OnBeforeStep(step PlannedStep)       { h.before = true }
+func (h *recordingHook) OnAfterStep(step PlannedStep)        { h.after = true }
+func (h *recordingHook) OnError(step PlannedStep, err error) { h.errored = true }
@@ -0,0 +1,86 @@
+package migrate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildStatusesDerivesAppliedFromCurrentVersionNotRowPresence(t *testing.T) {
+	appliedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	migrations := []Migration{
+		{Version: 1, Description: "add_users"},
+		{Version: 2, Description: "add_index"},
+	}
+
+	// Simulate: Up() applied v1 and v2, then Down(1) rolled v2 back. The log is append-only, so
+	// both the original v2 row and the new v1 row (written by Down's SetVersion call) are still
+	// present, even though v2 is no longer the current version.
+	rows := []versionRecord{
+		{Version: 1, Timestamp: appliedAt},
+		{Version: 2, Timestamp: appliedAt.Add(time.Minute)},
+		{Version: 1, Timestamp: appliedAt.Add(2 * time.Minute)}, // written when Down(1) reverted v2
+	}
+
+	statuses := buildStatuses(migrations, 1, rows)
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 statuses, got %d", len(statuses))
+	}
+	if !statuses[0].Applied {
+		t.Fatalf("expected version 1 to be applied, got %+v", statuses[0])
+	}
+	if statuses[1].Applied {
+		t.Fatalf("expected version 2 to be reported as reverted (not applied) despite its row still existing in the log, got %+v", statuses[1])
+	}
+}
+
+func TestBuildStatusesReportsMostRecentAppliedAt(t *testing.T) {
+	first := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+	migrations := []Migration{{Version: 1, Description: "add_users"}}
+	rows := []versionRecord{
+		{Version: 1, Timestamp: first},
+		{Version: 1, Timestamp: second},
+	}
+
+	statuses := buildStatuses(migrations, 1, rows)
+	if len(statuses) != 1 || !statuses[0].AppliedAt.Equal(second) {
+		t.Fatalf("expected AppliedAt %v (most recent row), got %+v", second, statuses)
+	}
+}
+
+func TestBuildStatusesWithNoRowsButForcedVersion(t *testing.T) {
+	// SetVersion can force a version with no per-migration rows at all.
+	migrations := []Migration{
+		{Version: 1, Description: "add_users"},
+		{Version: 2, Description: "add_index"},
+	}
+
+	statuses := buildStatuses(migrations, 2, nil)
+	if !statuses[0].Applied || !statuses[1].Applied {
+		t.Fatalf("expected both migrations applied when currentVersion is forced ahead of them, got %+v", statuses)
+	}
+	if !statuses[0].AppliedAt.IsZero() || !statuses[1].AppliedAt.IsZero() {
+		t.Fatalf("expected zero AppliedAt with no log rows, got %+v", statuses)
+	}
+}
+
+func TestPendingFiltersAppliedStatuses(t *testing.T) {
+	// Pending itself reads from the database, so this applies the same !Applied filter Pending
+	// uses to buildStatuses output rather than calling Pending.
+	migrations := []Migration{
+		{Version: 1, Description: "add_users"},
+		{Version: 2, Description: "add_index"},
+	}
+	statuses := buildStatuses(migrations, 1, nil)
+
+	var pending []MigrationStatus
+	for _, status := range statuses {
+		if !status.Applied {
+			pending = append(pending, status)
+		}
+	}
+
+	if len(pending) != 1 || pending[0].Version != 2 {
+		t.Fatalf("expected only version 2 pending, got %+v", pending)
+	}
+}
@@ -0,0 +1,46 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func TestIsTransactionsUnsupportedErr(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "illegal operation command error",
+			err:  mongo.CommandError{Code: illegalOperationCode, Name: "IllegalOperation", Message: "Transaction numbers are only allowed on a replica set member or mongos"},
+			want: true,
+		},
+		{
+			name: "wrapped illegal operation command error",
+			err:  fmt.Errorf("session error: %w", mongo.CommandError{Code: illegalOperationCode, Name: "IllegalOperation"}),
+			want: true,
+		},
+		{
+			name: "ordinary migration bug",
+			err:  errors.New("widgets collection: document validation failed"),
+			want: false,
+		},
+		{
+			name: "unrelated command error",
+			err:  mongo.CommandError{Code: 11000, Name: "DuplicateKey", Message: "E11000 duplicate key error"},
+			want: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := isTransactionsUnsupportedErr(c.err); got != c.want {
+				t.Fatalf("isTransactionsUnsupportedErr(%v) = %v, want %v", c.err, got, c.want)
+			}
+		})
+	}
+}